@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/creack/pty"
+	"github.com/mitranim/gg"
+)
+
+/*
+Owns the currently-running child process, if any. The child is always
+started in its own process group (negative PID addressing for
+`syscall.Kill`) so that signals sent via `Broadcast` reach any
+grandchildren the child itself forked, not just the immediate `go`/binary
+process.
+*/
+type Cmd struct {
+	main *Main
+
+	lock sync.Mutex
+	cmd  *exec.Cmd
+}
+
+func (self *Cmd) Init(main *Main) { self.main = main }
+
+func (self *Cmd) Deinit() { self.Broadcast(syscall.SIGKILL) }
+
+/*
+Starts a new child process, running `Opt.Args` via `go`. Does NOT stop any
+previously-running child; callers that want a clean handoff between runs
+must terminate the old one first (`Main.CmdRun` does this via the grace
+escalation path before calling `Restart` again).
+*/
+func (self *Cmd) Restart() {
+	self.lock.Lock()
+	cmd := exec.Command(`go`, self.main.Opt.Args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	var ptmx *os.File
+	var err error
+
+	if self.main.Opt.Pty {
+		// `pty.Start` sets `Setsid`, which already makes the child the leader
+		// of its own process group; `setpgid` on a session leader fails with
+		// EPERM, so `Setpgid` must be off here.
+		cmd.SysProcAttr.Setpgid = false
+		ptmx, err = pty.Start(cmd)
+	} else {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err = cmd.Start()
+	}
+
+	self.main.Opt.LogErr(err)
+	if err == nil {
+		self.cmd = cmd
+	} else {
+		self.cmd = nil
+		ptmx = nil
+	}
+	self.lock.Unlock()
+
+	if err == nil {
+		self.main.Stdio.Bridge(ptmx)
+		go self.main.CmdWait(cmd)
+	}
+}
+
+func (self *Cmd) IsRunning() bool {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return self.cmd != nil
+}
+
+func (self *Cmd) Pid() int {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if self.cmd == nil {
+		return 0
+	}
+	return self.cmd.Process.Pid
+}
+
+/*
+Sends `sig` to the entire process group of the current child, if any. Used
+both for forwarding received kill signals and, as of the grace-timeout
+escalation, for the follow-up `SIGKILL` when the child ignores the first
+signal.
+*/
+func (self *Cmd) Broadcast(sig syscall.Signal) {
+	self.lock.Lock()
+	cmd := self.cmd
+	self.lock.Unlock()
+
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	gg.Nop1(syscall.Kill(-cmd.Process.Pid, sig))
+}
+
+// Clears the current child reference. Called from `Main.CmdWait` once
+// `cmd.Wait` has returned, so that `IsRunning` reflects exited processes.
+func (self *Cmd) clear(cmd *exec.Cmd) {
+	self.lock.Lock()
+	if self.cmd == cmd {
+		self.cmd = nil
+	}
+	self.lock.Unlock()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mitranim/gg"
+)
+
+// Event types published on the `--event-socket`. Kept as string constants,
+// rather than an enum, since the wire format is JSON consumed by external
+// tools (editors, statuslines, CI dashboards).
+const (
+	EventTypeFsEvent    = `fs_event`
+	EventTypeRestart    = `restart`
+	EventTypeChildStart = `child_start`
+	EventTypeChildExit  = `child_exit`
+	EventTypeKillSignal = `kill_signal`
+)
+
+// A single line of the newline-delimited JSON protocol emitted on
+// `--event-socket`. Fields are optional and only populated as relevant to
+// `Type`.
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Path string    `json:"path,omitempty"`
+	Pid  int       `json:"pid,omitempty"`
+	Code int       `json:"code,omitempty"`
+	Sig  string    `json:"sig,omitempty"`
+}
+
+/*
+Fans out `Event` values as newline-delimited JSON to every client connected
+to `Opt.EventSocket`. Owned by `Main`; every subsystem that produces an
+interesting occurrence (`OnFsEvent`, `CmdRun`, `CmdWait`, `SigRun`) calls
+`Publish` directly. A slow or stuck client is dropped rather than allowed to
+block the hot path: each client gets a small buffered channel, and a full
+buffer means we disconnect that client instead of blocking `Publish`.
+*/
+type EventBus struct {
+	main *Main
+	path string
+
+	lock     sync.Mutex
+	listener net.Listener
+	clients  map[net.Conn]chan []byte
+}
+
+func (self *EventBus) Init(main *Main) {
+	self.main = main
+	self.path = main.Opt.EventSocket
+	self.clients = map[net.Conn]chan []byte{}
+
+	if self.path == `` {
+		return
+	}
+
+	gg.Nop1(os.Remove(self.path))
+
+	listener, err := net.Listen(`unix`, self.path)
+	main.Opt.LogErr(err)
+	self.listener = listener
+}
+
+func (self *EventBus) Deinit() {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if self.listener != nil {
+		gg.Nop1(self.listener.Close())
+		self.listener = nil
+	}
+	for conn, buf := range self.clients {
+		gg.Nop1(conn.Close())
+		close(buf)
+	}
+	self.clients = map[net.Conn]chan []byte{}
+
+	if self.path != `` {
+		gg.Nop1(os.Remove(self.path))
+	}
+}
+
+// Accept loop. Must run in its own goroutine; see `Main.Run`.
+func (self *EventBus) Run() {
+	if self.listener == nil {
+		return
+	}
+
+	for {
+		conn, err := self.listener.Accept()
+		if err != nil {
+			return
+		}
+		self.addClient(conn)
+	}
+}
+
+func (self *EventBus) addClient(conn net.Conn) {
+	buf := make(chan []byte, 64)
+
+	self.lock.Lock()
+	self.clients[conn] = buf
+	self.lock.Unlock()
+
+	go func() {
+		for chunk := range buf {
+			if _, err := conn.Write(chunk); err != nil {
+				break
+			}
+		}
+		self.removeClient(conn)
+	}()
+}
+
+func (self *EventBus) removeClient(conn net.Conn) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if buf, ok := self.clients[conn]; ok {
+		delete(self.clients, conn)
+		close(buf)
+	}
+	gg.Nop1(conn.Close())
+}
+
+func (self *EventBus) Publish(event Event) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if len(self.clients) == 0 {
+		return
+	}
+
+	event.Time = time.Now()
+	chunk, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	chunk = append(chunk, '\n')
+
+	for conn, buf := range self.clients {
+		select {
+		case buf <- chunk:
+		default:
+			// Slow consumer; drop it rather than block the publisher.
+			delete(self.clients, conn)
+			close(buf)
+			gg.Nop1(conn.Close())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// A client that never reads its side of the connection must eventually be
+// dropped, rather than `Publish` blocking on (or unboundedly queuing for)
+// a stuck consumer.
+func TestEventBus_PublishDropsSlowConsumer(t *testing.T) {
+	var bus EventBus
+	bus.clients = map[net.Conn]chan []byte{}
+
+	server, client := net.Pipe()
+	defer client.Close()
+	// Deliberately never read from `client`, so the buffered-channel
+	// writer goroutine backs up and `Publish` has to start dropping.
+	bus.addClient(server)
+
+	for i := 0; i < 128; i++ {
+		bus.Publish(Event{Type: EventTypeFsEvent, Path: `main.go`})
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		bus.lock.Lock()
+		n := len(bus.clients)
+		bus.lock.Unlock()
+
+		if n == 0 {
+			return
+		}
+
+		select {
+		case <-deadline:
+			t.Fatalf(`expected the slow client to be dropped, but it is still registered`)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -35,9 +35,12 @@ type Main struct {
 	Stdio       Stdio
 	Watcher     Watcher
 	TermState   TermState
+	EventBus    EventBus
+	SmartGraph  SmartGraph
 	ChanSignals gg.Chan[os.Signal]
-	ChanRestart gg.Chan[struct{}]
+	ChanRestart gg.Chan[string]
 	ChanKill    gg.Chan[syscall.Signal]
+	ChanDone    gg.Chan[int]
 	lastRestart time.Time
 }
 
@@ -46,12 +49,20 @@ func (self *Main) Init() {
 
 	self.ChanRestart.Init()
 	self.ChanKill.Init()
+	// Buffered deep enough to hold a few stale exit notifications (children
+	// that exited on their own between two `StopChild` calls) without the
+	// sending `CmdWait` goroutine blocking; see `StopChild`.
+	self.ChanDone.InitCap(8)
 
 	self.Cmd.Init(self)
 	self.SigInit()
 	self.WatchInit()
 	self.TermState.Init(self)
 	self.Stdio.Init(self)
+	self.EventBus.Init(self)
+	if self.Opt.Smart {
+		self.SmartGraph.Init(self)
+	}
 	self.lastRestart = time.Now()
 }
 
@@ -75,12 +86,20 @@ func (self *Main) Deinit() {
 	self.WatchDeinit()
 	self.SigDeinit()
 	self.Cmd.Deinit()
+	self.EventBus.Deinit()
 }
 
 func (self *Main) Run() {
-	go self.Stdio.Run()
+	// In `-P` mode, `os.Stdin` belongs entirely to `Stdio.Bridge`, which
+	// copies it verbatim into the child's PTY; running the stdin-command
+	// loop concurrently would race it for bytes typed for the child. See
+	// `Stdio.Run`.
+	if !self.Opt.Pty {
+		go self.Stdio.Run()
+	}
 	go self.SigRun()
 	go self.WatchRun()
+	go self.EventBus.Run()
 	self.CmdRun()
 }
 
@@ -94,7 +113,9 @@ handled by this program; see below.
 */
 func (self *Main) SigInit() {
 	self.ChanSignals.InitCap(1)
-	signal.Notify(self.ChanSignals, KILL_SIGS_OS...)
+	signal.Notify(self.ChanSignals, sigsOs(self.Opt.KillSigs())...)
+	signal.Notify(self.ChanSignals, sigsOs(self.Opt.ControlSigs())...)
+	signal.Notify(self.ChanSignals, syscall.SIGWINCH)
 }
 
 func (self *Main) SigDeinit() {
@@ -103,29 +124,98 @@ func (self *Main) SigDeinit() {
 	}
 }
 
+/*
+Control-signal roles, keyed by position in `Opt.ControlSigs()` rather than
+by a fixed syscall identity, so that `-S "control=..."` actually changes
+which signal triggers which behavior instead of just which signal gets
+registered with `signal.Notify`.
+*/
+const (
+	controlRoleRestart = iota
+	controlRoleStatus
+	controlRoleVerbToggle
+)
+
 func (self *Main) SigRun() {
 	for val := range self.ChanSignals {
 		// Should work on all Unix systems. At the time of writing,
 		// we're not prepared to support other systems.
 		sig := val.(syscall.Signal)
 
-		if gg.Has(KILL_SIGS, sig) {
-			if self.Opt.Verb {
+		switch {
+		// `-S` lets a user move a signal out of the control table and into
+		// the kill table (or vice versa); an explicit kill override always
+		// wins over the positional control roles below.
+		case gg.Has(self.Opt.KillSigs(), sig):
+			if self.Opt.Verb() {
 				log.Println(`received kill signal:`, sig)
 			}
+			self.EventBus.Publish(Event{Type: EventTypeKillSignal, Sig: sig.String()})
 			self.Kill(sig)
-			continue
+
+		case self.controlRole(sig) == controlRoleRestart:
+			if self.Opt.Verb() {
+				log.Println(`received control signal, restarting:`, sig)
+			}
+			self.Restart()
+
+		case self.controlRole(sig) == controlRoleStatus:
+			self.Status()
+
+		case self.controlRole(sig) == controlRoleVerbToggle:
+			log.Println(`verbose logging:`, self.Opt.ToggleVerb())
+
+		case sig == syscall.SIGWINCH:
+			self.Stdio.Resize()
+
+		default:
+			if self.Opt.Verb() {
+				log.Println(`received unknown signal:`, sig)
+			}
 		}
+	}
+}
 
-		if self.Opt.Verb {
-			log.Println(`received unknown signal:`, sig)
+// Returns the index of `sig` in `Opt.ControlSigs()`, or -1 if `sig` isn't
+// currently registered as a control signal. The index is the role: see
+// `controlRoleRestart` et al.
+func (self *Main) controlRole(sig syscall.Signal) int {
+	for i, val := range self.Opt.ControlSigs() {
+		if val == sig {
+			return i
 		}
 	}
+	return -1
+}
+
+/*
+Prints a one-line status snapshot to stderr. Invoked on SIGUSR1; see
+`Main.SigRun`. Intended for `pkill -USR1 gow` style scripting, where a user
+or supervisor wants to check in on a long-running watch loop without
+attaching to its stdout/stderr.
+*/
+func (self *Main) Status() {
+	log.Printf(
+		`status: child_pid=%v last_restart=%v roots=%v debounce=%v`,
+		self.Cmd.Pid(), self.lastRestart.Format(time.RFC3339), self.Opt.WatchRoots(), time.Duration(self.Opt.Debounce),
+	)
 }
 
 func (self *Main) WatchInit() {
-	wat := new(WatchNotify)
-	wat.Init(self)
+	var wat Watcher
+
+	switch self.Opt.Watch {
+	case `poll`:
+		wat = new(WatchPoll)
+	case `none`:
+		wat = nil
+	default:
+		wat = new(WatchNotify)
+	}
+
+	if wat != nil {
+		wat.Init(self)
+	}
 	self.Watcher = wat
 }
 
@@ -145,14 +235,21 @@ func (self *Main) WatchRun() {
 func (self *Main) CmdRun() {
 	if !self.Opt.Postpone {
 		self.Cmd.Restart()
+		self.EventBus.Publish(Event{Type: EventTypeChildStart, Pid: self.Cmd.Pid()})
 	}
 
 	for {
 		select {
-		case <-self.ChanRestart:
+		case path := <-self.ChanRestart:
 			self.lastRestart = time.Now()
+			self.EventBus.Publish(Event{Type: EventTypeRestart, Path: path})
 			self.Opt.TermInter()
+			self.StopChild(syscall.SIGTERM)
+			if self.Opt.Smart {
+				self.SmartGraph.Rewrite(path)
+			}
 			self.Cmd.Restart()
+			self.EventBus.Publish(Event{Type: EventTypeChildStart, Pid: self.Cmd.Pid()})
 
 		case sig := <-self.ChanKill:
 			self.Terminate(sig)
@@ -162,8 +259,72 @@ func (self *Main) CmdRun() {
 }
 
 func (self *Main) CmdWait(cmd *exec.Cmd) {
-	self.Opt.LogSubErr(cmd.Wait())
+	err := cmd.Wait()
+	self.Opt.LogSubErr(err)
+	self.Cmd.clear(cmd)
 	self.Opt.TermSuf()
+	code := cmd.ProcessState.ExitCode()
+	self.EventBus.Publish(Event{Type: EventTypeChildExit, Pid: cmd.Process.Pid, Code: code})
+	if self.Opt.Smart && code == 0 {
+		go self.SmartGraph.Build()
+	}
+	self.ChanDone.SendOpt(cmd.Process.Pid)
+}
+
+/*
+Sends `sig` to the current child's process group and waits up to
+`Opt.GraceTimeout` for it to exit, mirroring Kubernetes pod termination
+(SIGTERM, then a grace window, then SIGKILL). If the child has not exited
+by the deadline, escalates to `SIGKILL` on the whole process group and logs
+a warning. A no-op if no child is currently running.
+
+`ChanDone` carries the pid of whichever child just exited, since it's also
+fed by children that exit on their own (the normal case for `gow build`/
+`gow test`) with no `StopChild` call in flight to consume the
+notification. Without filtering by pid, such a leftover notification would
+be mistaken for the exit of a *different*, still-running child that a
+later `StopChild` call is actually waiting on — exactly the zombie-child
+scenario this method exists to prevent. So we drain and discard any pid
+that doesn't match the child we just signaled.
+
+Used both for a plain restart (so a fast edit loop doesn't leak zombie
+children holding ports) and, via `Terminate`, for gow's own shutdown.
+*/
+func (self *Main) StopChild(sig syscall.Signal) {
+	pid := self.Cmd.Pid()
+	if pid == 0 {
+		return
+	}
+
+	self.Cmd.Broadcast(sig)
+
+	timer := time.NewTimer(self.Opt.GraceTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case donePid := <-self.ChanDone:
+			if donePid == pid {
+				return
+			}
+
+		case <-timer.C:
+			log.Println(`child did not exit within grace period, sending SIGKILL`)
+			self.Cmd.Broadcast(syscall.SIGKILL)
+			self.awaitPid(pid)
+			return
+		}
+	}
+}
+
+// Blocks until `ChanDone` reports the exit of `pid`, discarding any stale
+// notifications for already-reaped children along the way.
+func (self *Main) awaitPid(pid int) {
+	for donePid := range self.ChanDone {
+		if donePid == pid {
+			return
+		}
+	}
 }
 
 // Must be deferred.
@@ -177,13 +338,15 @@ func (self *Main) Exit() {
 }
 
 func (self *Main) OnFsEvent(event FsEvent) {
+	self.EventBus.Publish(Event{Type: EventTypeFsEvent, Path: event.Path()})
+
 	if !self.ShouldRestart(event) {
 		return
 	}
-	if self.Opt.Verb {
+	if self.Opt.Verb() {
 		log.Println(`restarting on FS event:`, event)
 	}
-	self.Restart()
+	self.RestartFor(event.Path())
 }
 
 func (self *Main) ShouldRestart(event FsEvent) bool {
@@ -193,18 +356,20 @@ func (self *Main) ShouldRestart(event FsEvent) bool {
 		self.Opt.Debounce.Allow(self.lastRestart)
 }
 
-func (self *Main) Restart() { self.ChanRestart.SendZeroOpt() }
+func (self *Main) Restart() { self.RestartFor(``) }
+
+// Like `Restart`, but records which file triggered it, so `Opt.Smart` can
+// narrow the next run to the packages that file affects.
+func (self *Main) RestartFor(path string) { self.ChanRestart.SendOpt(path) }
 
 func (self *Main) Kill(val syscall.Signal) { self.ChanKill.SendOpt(val) }
 
 func (self *Main) Terminate(sig syscall.Signal) {
 	/**
-	This should terminate any descendant processes, using their default behavior
-	for the given signal. If any misbehaving processes do not terminate on a
-	kill signal, this is out of our hands for now. We could use SIGKILL to
-	ensure termination, but it's unclear if we should.
+	Gives the child a grace window to exit on its own before we escalate to
+	SIGKILL. See `StopChild` for the Kubernetes-style two-phase shutdown.
 	*/
-	self.Cmd.Broadcast(sig)
+	self.StopChild(sig)
 
 	/**
 	This should restore previous terminal state and un-register our custom signal
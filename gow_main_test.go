@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newTestMain(t *testing.T, graceTimeout time.Duration) *Main {
+	t.Helper()
+
+	main := new(Main)
+	main.Opt.GraceTimeout = graceTimeout
+	main.ChanDone.InitCap(8)
+	main.Cmd.Init(main)
+	return main
+}
+
+// Starts a real child process and wires it up the same way `Cmd.Restart`
+// would, without going through `exec.Command("go", ...)`, so tests can
+// exercise arbitrary shell snippets.
+func startTestChild(t *testing.T, main *Main, script string) *exec.Cmd {
+	t.Helper()
+
+	cmd := exec.Command(`sh`, `-c`, script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
+		t.Fatalf(`failed to start test child: %v`, err)
+	}
+
+	main.Cmd.lock.Lock()
+	main.Cmd.cmd = cmd
+	main.Cmd.lock.Unlock()
+
+	go main.CmdWait(cmd)
+	return cmd
+}
+
+// A child that exits promptly on SIGTERM should not trigger the SIGKILL
+// escalation, and `StopChild` should return as soon as it does.
+func TestStopChild_GracefulExit(t *testing.T) {
+	main := newTestMain(t, time.Second)
+	startTestChild(t, main, `sleep 5`)
+
+	start := time.Now()
+	main.StopChild(syscall.SIGTERM)
+	elapsed := time.Since(start)
+
+	if elapsed >= time.Second {
+		t.Fatalf(`StopChild took %v, expected it to return well before the grace timeout`, elapsed)
+	}
+	if main.Cmd.IsRunning() {
+		t.Fatalf(`expected child to be reaped after StopChild returns`)
+	}
+}
+
+// A child that ignores SIGTERM must be escalated to SIGKILL once the grace
+// window elapses.
+func TestStopChild_KillEscalation(t *testing.T) {
+	main := newTestMain(t, 50*time.Millisecond)
+	startTestChild(t, main, `trap '' TERM; sleep 5`)
+
+	done := make(chan struct{})
+	go func() {
+		main.StopChild(syscall.SIGTERM)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf(`StopChild did not return after escalating to SIGKILL`)
+	}
+}
+
+/*
+Regression test for a bug where `ChanDone` carried no identity: a child
+that had already exited on its own (the normal case for `gow build`/`gow
+test`) left a token in the buffer that a later `StopChild` call for a
+*different*, still-running child would consume immediately, returning as
+if that still-running child had exited. `StopChild` must discard pids that
+don't match the child it's currently signaling.
+*/
+func TestStopChild_IgnoresStaleTokenForDifferentChild(t *testing.T) {
+	main := newTestMain(t, time.Second)
+
+	// Simulate a stale notification left behind by an unrelated, already-
+	// reaped child.
+	main.ChanDone.SendOpt(999999)
+
+	startTestChild(t, main, `sleep 5`)
+
+	start := time.Now()
+	main.StopChild(syscall.SIGTERM)
+	elapsed := time.Since(start)
+
+	if elapsed >= time.Second {
+		t.Fatalf(`StopChild took %v; it should have ignored the stale token and waited for the real child`, elapsed)
+	}
+	if main.Cmd.IsRunning() {
+		t.Fatalf(`expected the real child to be reaped after StopChild returns`)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/mitranim/gg"
+)
+
+/*
+Command-line options for gow itself, parsed out of `os.Args` before the
+wrapped "go" command and its own arguments. Everything after the
+recognized gow flags is forwarded verbatim to `Cmd`, except that `-smart`
+mode may replace a "..." package pattern; see `SmartGraph.Rewrite`.
+*/
+type Opt struct {
+	Postpone bool
+	Lazy     bool
+	Debounce Debounce
+	Signals  string
+	Args     []string
+
+	Watch       string
+	WatchIgnore string
+	PollFreq    time.Duration
+	PollDepth   int
+
+	GraceTimeout time.Duration
+	Pty          bool
+	EventSocket  string
+	Smart        bool
+
+	// Toggled at runtime by SIGUSR2 (see `Main.SigRun`), and read from every
+	// goroutine in this program, so it's an `atomic.Bool` rather than a
+	// plain field.
+	verb atomic.Bool
+
+	killSigs    []syscall.Signal
+	controlSigs []syscall.Signal
+}
+
+func (self *Opt) Init(args []string) {
+	set := flag.NewFlagSet(`gow`, flag.ExitOnError)
+
+	verb := set.Bool(`v`, false, `verbose logging`)
+	set.BoolVar(&self.Postpone, `p`, false, `postpone the first run until the first FS event`)
+	set.BoolVar(&self.Lazy, `l`, false, `skip FS events while the child is still running`)
+	set.DurationVar((*time.Duration)(&self.Debounce), `d`, 500*time.Millisecond, `debounce window for FS events`)
+	set.StringVar(&self.Signals, `S`, ``, `override the kill/control signal tables, format "kill=INT,TERM;control=HUP,USR1,USR2"`)
+	set.StringVar(&self.Signals, `signals`, ``, `long form of -S`)
+	set.StringVar(&self.Watch, `w`, `notify`, `watch backend: "notify" (default), "poll", or "none"`)
+	set.StringVar(&self.WatchIgnore, `watch-ignore`, ``, `comma-separated glob patterns to ignore, used by both watch backends`)
+	set.DurationVar(&self.PollFreq, `poll-interval`, time.Second, `poll interval, only relevant when -w poll`)
+	set.IntVar(&self.PollDepth, `poll-depth`, 0, `max directory depth to walk when polling, 0 means unlimited`)
+	set.DurationVar(&self.GraceTimeout, `g`, 10*time.Second, `grace period between a kill signal and forcibly SIGKILLing the child`)
+	set.BoolVar(&self.Pty, `P`, false, `run the child attached to a pseudo-terminal, for color/TUI detection`)
+	set.StringVar(&self.EventSocket, `event-socket`, ``, `path to a unix socket to publish newline-delimited JSON events on`)
+	set.BoolVar(&self.Smart, `smart`, false, `rerun only the packages affected by the changed file, via the reverse import graph`)
+
+	gg.Nop1(set.Parse(args))
+	self.Args = set.Args()
+	self.verb.Store(*verb)
+
+	self.killSigs, self.controlSigs = parseSignals(self.Signals)
+}
+
+func (self *Opt) Verb() bool { return self.verb.Load() }
+
+func (self *Opt) SetVerb(val bool) { self.verb.Store(val) }
+
+// Flips `Verb` and returns the new value. Used by the SIGUSR2 handler.
+func (self *Opt) ToggleVerb() bool {
+	for {
+		old := self.verb.Load()
+		if self.verb.CompareAndSwap(old, !old) {
+			return !old
+		}
+	}
+}
+
+/*
+Returns the effective set of signals that should terminate the child, after
+applying any `-S` override. Falls back to the package-level `KILL_SIGS`.
+*/
+func (self *Opt) KillSigs() []syscall.Signal {
+	if self.killSigs != nil {
+		return self.killSigs
+	}
+	return KILL_SIGS
+}
+
+/*
+Returns the effective set of signals treated as control commands (restart,
+status, verbosity toggle), after applying any `-S` override. Falls back to
+the package-level `CONTROL_SIGS`.
+*/
+func (self *Opt) ControlSigs() []syscall.Signal {
+	if self.controlSigs != nil {
+		return self.controlSigs
+	}
+	return CONTROL_SIGS
+}
+
+// Parses the `-S` flag. Empty input means "use the defaults".
+func parseSignals(src string) (kill []syscall.Signal, control []syscall.Signal) {
+	if src == `` {
+		return nil, nil
+	}
+
+	for _, part := range strings.Split(src, `;`) {
+		key, val, found := strings.Cut(part, `=`)
+		if !found {
+			continue
+		}
+
+		sigs := parseSigNames(val)
+
+		switch strings.TrimSpace(key) {
+		case `kill`:
+			kill = sigs
+		case `control`:
+			control = sigs
+		}
+	}
+	return
+}
+
+func parseSigNames(src string) []syscall.Signal {
+	var out []syscall.Signal
+	for _, name := range strings.Split(src, `,`) {
+		name = strings.TrimSpace(name)
+		if name == `` {
+			continue
+		}
+		if sig, ok := sigByName[strings.ToUpper(name)]; ok {
+			out = append(out, sig)
+		}
+	}
+	return out
+}
+
+var sigByName = map[string]syscall.Signal{
+	`HUP`:  syscall.SIGHUP,
+	`INT`:  syscall.SIGINT,
+	`QUIT`: syscall.SIGQUIT,
+	`TERM`: syscall.SIGTERM,
+	`USR1`: syscall.SIGUSR1,
+	`USR2`: syscall.SIGUSR2,
+}
+
+// Debounce window. Duration-typed so it plugs directly into `flag.DurationVar`.
+type Debounce time.Duration
+
+func (self Debounce) Allow(lastRestart time.Time) bool {
+	return time.Since(lastRestart) >= time.Duration(self)
+}
+
+func (self *Opt) AllowPath(path string) bool { return path != `` && !self.IsIgnored(path) }
+
+// Directories to watch. For now this is just the current directory; `gow`
+// has no separate `-r`/roots flag, so both watch backends default to `cwd`.
+func (self *Opt) WatchRoots() []string { return []string{cwd} }
+
+func (self *Opt) PollInterval() time.Duration { return self.PollFreq }
+
+func (self *Opt) PollMaxDepth() int { return self.PollDepth }
+
+func (self *Opt) IsIgnored(path string) bool {
+	for _, pattern := range strings.Split(self.WatchIgnore, `,`) {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == `` {
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(path)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *Opt) LogErr(err error) {
+	if err != nil {
+		log.Println(`error:`, err)
+	}
+}
+
+func (self *Opt) LogSubErr(err error) {
+	if err != nil {
+		log.Println(`subprocess error:`, err)
+	}
+}
+
+func (self *Opt) TermSuf() {}
+
+func (self *Opt) TermInter() {}
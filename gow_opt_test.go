@@ -0,0 +1,75 @@
+package main
+
+import (
+	"syscall"
+	"testing"
+)
+
+func sameSigs(t *testing.T, got, want []syscall.Signal) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf(`got %v, want %v`, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf(`got %v, want %v`, got, want)
+		}
+	}
+}
+
+func TestParseSignals_Empty(t *testing.T) {
+	kill, control := parseSignals(``)
+	if kill != nil || control != nil {
+		t.Fatalf(`expected no override for empty input, got kill=%v control=%v`, kill, control)
+	}
+}
+
+func TestParseSignals_KillOnly(t *testing.T) {
+	kill, control := parseSignals(`kill=HUP,USR1`)
+	sameSigs(t, kill, []syscall.Signal{syscall.SIGHUP, syscall.SIGUSR1})
+	if control != nil {
+		t.Fatalf(`expected no control override, got %v`, control)
+	}
+}
+
+func TestParseSignals_KillAndControl(t *testing.T) {
+	kill, control := parseSignals(`kill=INT,TERM;control=HUP,USR1,USR2`)
+	sameSigs(t, kill, []syscall.Signal{syscall.SIGINT, syscall.SIGTERM})
+	sameSigs(t, control, []syscall.Signal{syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2})
+}
+
+func TestParseSignals_IgnoresUnknownNamesAndWhitespace(t *testing.T) {
+	kill, _ := parseSignals(`kill= HUP , BOGUS ,USR1 `)
+	sameSigs(t, kill, []syscall.Signal{syscall.SIGHUP, syscall.SIGUSR1})
+}
+
+func TestOpt_KillSigsAndControlSigsFallBackToDefaults(t *testing.T) {
+	var opt Opt
+	opt.Init(nil)
+
+	sameSigs(t, opt.KillSigs(), KILL_SIGS)
+	sameSigs(t, opt.ControlSigs(), CONTROL_SIGS)
+}
+
+func TestOpt_SignalsOverridesKillSigs(t *testing.T) {
+	var opt Opt
+	opt.Init([]string{`-S`, `kill=HUP,USR1`})
+
+	sameSigs(t, opt.KillSigs(), []syscall.Signal{syscall.SIGHUP, syscall.SIGUSR1})
+}
+
+func TestOpt_VerbAtomicToggle(t *testing.T) {
+	var opt Opt
+	opt.Init(nil)
+
+	if opt.Verb() {
+		t.Fatalf(`expected Verb to default to false`)
+	}
+	if !opt.ToggleVerb() || !opt.Verb() {
+		t.Fatalf(`expected ToggleVerb to flip Verb to true`)
+	}
+	if opt.ToggleVerb() || opt.Verb() {
+		t.Fatalf(`expected ToggleVerb to flip Verb back to false`)
+	}
+}
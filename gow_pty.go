@@ -0,0 +1,20 @@
+package main
+
+import (
+	"os"
+
+	"github.com/creack/pty"
+)
+
+/*
+Resizes `ptmx` to match our own stdout, which is assumed to be a terminal
+when `Opt.Pty` is enabled. Called on startup and on every SIGWINCH; see
+`Stdio.Resize` and `Main.SigRun`.
+*/
+func resizePty(ptmx *os.File) error {
+	size, err := pty.GetsizeFull(os.Stdout)
+	if err != nil {
+		return err
+	}
+	return pty.Setsize(ptmx, size)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+/*
+Default set of signals that terminate the child (and, via `Main.Terminate`,
+gow itself, after cleanup). This is only the default: `Opt.KillSigs()`
+returns this table unless overridden at the process level by `Opt.Signals`
+(see the `-S` / `--signals` flag in `Opt.Init`), in which case an override
+entirely replaces it — `Main.SigInit`/`SigRun` always go through
+`Opt.KillSigs()`, never this variable directly.
+*/
+var KILL_SIGS = []syscall.Signal{
+	syscall.SIGINT,
+	syscall.SIGTERM,
+	syscall.SIGQUIT,
+}
+
+/*
+Default set of signals treated as control commands rather than kill
+signals, keyed by position (see `controlRoleRestart` et al in
+`Main.SigRun`):
+
+	* SIGHUP:  equivalent to the `r` stdin command; see `Main.Restart`.
+	* SIGUSR1: print current status to stderr; see `Main.Status`.
+	* SIGUSR2: toggle `Opt.Verb` at runtime.
+
+Like `KILL_SIGS`, this is only the default. `-S control=...` replaces it
+wholesale via `Opt.ControlSigs()`, which is what `Main.SigInit`/`SigRun`
+actually consult.
+*/
+var CONTROL_SIGS = []syscall.Signal{
+	syscall.SIGHUP,
+	syscall.SIGUSR1,
+	syscall.SIGUSR2,
+}
+
+func sigsOs(sigs []syscall.Signal) []os.Signal {
+	out := make([]os.Signal, 0, len(sigs))
+	for _, val := range sigs {
+		out = append(out, val)
+	}
+	return out
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Cap on how many packages we're willing to substitute into argv before
+// giving up and falling back to the original arguments (typically
+// `./...`). Keeps a broad, repo-wide change from producing an argv longer
+// than `go test` wants to deal with.
+const smartMaxPackages = 64
+
+/*
+Reverse import graph used by `Opt.Smart` mode to rerun only the packages
+affected by a changed file, instead of always rerunning `Opt.Args`
+verbatim. Built from `go list -deps -json ./...`, which conveniently
+already expands each package's `Deps` transitively, so a single pass over
+the package list is enough to invert it: for every package P and every
+dependency D in P.Deps, P is a (transitive) importer of D.
+*/
+type SmartGraph struct {
+	main *Main
+
+	lock      sync.Mutex
+	fileToPkg map[string]string
+	importers map[string]map[string]bool
+	origArgs  []string
+}
+
+func (self *SmartGraph) Init(main *Main) {
+	self.main = main
+	self.origArgs = append([]string(nil), main.Opt.Args...)
+	self.Build()
+}
+
+// Rebuilds the graph. Safe to call repeatedly; called on startup, after
+// every successful build, and whenever `go.mod`/`go.sum` changes.
+func (self *SmartGraph) Build() {
+	out, err := exec.Command(`go`, `list`, `-deps`, `-json`, `./...`).Output()
+	self.main.Opt.LogErr(err)
+	if err != nil {
+		return
+	}
+
+	fileToPkg := map[string]string{}
+	importers := map[string]map[string]bool{}
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg smartPkg
+		if dec.Decode(&pkg) != nil {
+			break
+		}
+
+		for _, file := range pkg.allFiles() {
+			fileToPkg[filepath.Join(pkg.Dir, file)] = pkg.ImportPath
+		}
+
+		if importers[pkg.ImportPath] == nil {
+			importers[pkg.ImportPath] = map[string]bool{}
+		}
+		for _, dep := range pkg.Deps {
+			if importers[dep] == nil {
+				importers[dep] = map[string]bool{}
+			}
+			importers[dep][pkg.ImportPath] = true
+		}
+	}
+
+	self.lock.Lock()
+	self.fileToPkg = fileToPkg
+	self.importers = importers
+	self.lock.Unlock()
+}
+
+type smartPkg struct {
+	ImportPath   string
+	Dir          string
+	GoFiles      []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+	Imports      []string
+	Deps         []string
+}
+
+func (self smartPkg) allFiles() []string {
+	out := make([]string, 0, len(self.GoFiles)+len(self.TestGoFiles)+len(self.XTestGoFiles))
+	out = append(out, self.GoFiles...)
+	out = append(out, self.TestGoFiles...)
+	out = append(out, self.XTestGoFiles...)
+	return out
+}
+
+/*
+Rewrites `main.Opt.Args` for the upcoming restart to target only the
+packages affected by `path`, if we can compute a reasonably small affected
+set; otherwise leaves `Opt.Args` as the original, unrestricted arguments.
+Invoked from `CmdRun` just before `self.Cmd.Restart()`.
+*/
+func (self *SmartGraph) Rewrite(path string) {
+	if isModFile(path) {
+		self.Build()
+	}
+
+	affected := self.affectedPackages(path)
+
+	if len(affected) == 0 || len(affected) > smartMaxPackages {
+		self.main.Opt.Args = append([]string(nil), self.origArgs...)
+		return
+	}
+
+	self.main.Opt.Args = rewriteArgsWithPackages(self.origArgs, affected)
+}
+
+func (self *SmartGraph) affectedPackages(path string) []string {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	pkg, ok := self.fileToPkg[path]
+	if !ok {
+		return nil
+	}
+
+	set := map[string]bool{pkg: true}
+	for importer := range self.importers[pkg] {
+		set[importer] = true
+	}
+
+	out := make([]string, 0, len(set))
+	for name := range set {
+		out = append(out, name)
+	}
+	return out
+}
+
+// Replaces the first argument ending in "..." (such as "./...") with the
+// concrete affected package list, leaving every other argument, flags
+// included, untouched. Bare package paths without "..." are not replaced;
+// if no argument ends in "...", returns a copy of `origArgs` unchanged.
+func rewriteArgsWithPackages(origArgs []string, pkgs []string) []string {
+	out := make([]string, 0, len(origArgs)+len(pkgs))
+	replaced := false
+
+	for _, arg := range origArgs {
+		if !replaced && strings.HasSuffix(arg, `...`) {
+			out = append(out, pkgs...)
+			replaced = true
+			continue
+		}
+		out = append(out, arg)
+	}
+
+	if !replaced {
+		return append([]string(nil), origArgs...)
+	}
+	return out
+}
+
+func isModFile(path string) bool {
+	base := filepath.Base(path)
+	return base == `go.mod` || base == `go.sum`
+}
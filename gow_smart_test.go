@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRewriteArgsWithPackages_ReplacesTrailingEllipsis(t *testing.T) {
+	origArgs := []string{`test`, `-v`, `./...`}
+	pkgs := []string{`example.com/mod/foo`, `example.com/mod/bar`}
+
+	got := rewriteArgsWithPackages(origArgs, pkgs)
+	want := []string{`test`, `-v`, `example.com/mod/foo`, `example.com/mod/bar`}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf(`rewriteArgsWithPackages(%v, %v) = %v, want %v`, origArgs, pkgs, got, want)
+	}
+}
+
+func TestRewriteArgsWithPackages_OnlyReplacesFirstMatch(t *testing.T) {
+	origArgs := []string{`build`, `./...`, `./cmd/...`}
+	pkgs := []string{`example.com/mod/foo`}
+
+	got := rewriteArgsWithPackages(origArgs, pkgs)
+	want := []string{`build`, `example.com/mod/foo`, `./cmd/...`}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf(`rewriteArgsWithPackages(%v, %v) = %v, want %v`, origArgs, pkgs, got, want)
+	}
+}
+
+func TestRewriteArgsWithPackages_NoEllipsisFallsBackToOrigArgs(t *testing.T) {
+	origArgs := []string{`run`, `example.com/mod/cmd/foo`}
+	pkgs := []string{`example.com/mod/bar`}
+
+	got := rewriteArgsWithPackages(origArgs, pkgs)
+
+	if !reflect.DeepEqual(got, origArgs) {
+		t.Fatalf(`rewriteArgsWithPackages(%v, %v) = %v, want unchanged %v`, origArgs, pkgs, got, origArgs)
+	}
+
+	// Must be a copy, not an alias, so later mutation of the result can't
+	// reach back into `origArgs`.
+	got[0] = `mutated`
+	if origArgs[0] == `mutated` {
+		t.Fatalf(`expected rewriteArgsWithPackages to return a copy of origArgs`)
+	}
+}
+
+func TestIsModFile(t *testing.T) {
+	cases := map[string]bool{
+		`go.mod`:            true,
+		`go.sum`:            true,
+		`/repo/dir/go.mod`:  true,
+		`/repo/dir/main.go`: false,
+		``:                  false,
+	}
+	for path, want := range cases {
+		if got := isModFile(path); got != want {
+			t.Fatalf(`isModFile(%q) = %v, want %v`, path, got, want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mitranim/gg"
+)
+
+/*
+Reads commands from stdin and, in `-P`/PTY mode, bridges stdio bytes to and
+from the child's pseudo-terminal. The two jobs share a file because both
+need exclusive ownership of `os.Stdin`.
+*/
+type Stdio struct {
+	main *Main
+
+	lock   sync.Mutex
+	ptmx   *os.File
+	bridge chan struct{}
+}
+
+func (self *Stdio) Init(main *Main) { self.main = main }
+
+func (self *Stdio) Deinit() { self.unbridge() }
+
+/*
+Reads newline-delimited commands from stdin. Currently supports `r`, which
+triggers the same restart as an FS event.
+
+Must NOT be run in `-P` mode: `Stdio.Bridge` takes exclusive ownership of
+`os.Stdin` there, copying it verbatim into the child's PTY, and a
+concurrent blocking read here would race it for bytes typed for the
+child's TUI. `Main.Run` only starts this loop when `!Opt.Pty`.
+*/
+func (self *Stdio) Run() {
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch line {
+		case "r\n", "r\r\n":
+			self.main.Restart()
+		}
+	}
+}
+
+// Starts copying bytes between the child's PTY and our own stdio. Called
+// from `Cmd.Restart` after a PTY child has started; a no-op otherwise.
+func (self *Stdio) Bridge(ptmx *os.File) {
+	self.unbridge()
+	if ptmx == nil {
+		return
+	}
+
+	self.lock.Lock()
+	self.ptmx = ptmx
+	self.bridge = make(chan struct{})
+	self.lock.Unlock()
+
+	self.Resize()
+
+	go func() { gg.Nop1(ioCopyUntilClosed(ptmx, os.Stdin, self.bridge)) }()
+	go func() { gg.Nop1(ioCopyUntilClosed(os.Stdout, ptmx, self.bridge)) }()
+}
+
+func (self *Stdio) unbridge() {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if self.bridge != nil {
+		close(self.bridge)
+		self.bridge = nil
+	}
+	if self.ptmx != nil {
+		gg.Nop1(self.ptmx.Close())
+		self.ptmx = nil
+	}
+}
+
+// Forwards the current terminal size to the child's PTY. Invoked on
+// SIGWINCH by `Main.SigRun`; see `Main.SigInit`.
+func (self *Stdio) Resize() {
+	self.lock.Lock()
+	ptmx := self.ptmx
+	self.lock.Unlock()
+
+	if ptmx != nil {
+		self.main.Opt.LogErr(resizePty(ptmx))
+	}
+}
+
+func ioCopyUntilClosed(dst io.Writer, src io.Reader, done chan struct{}) error {
+	_, err := io.Copy(dst, src)
+	select {
+	case <-done:
+		return nil
+	default:
+		return err
+	}
+}
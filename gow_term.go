@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+
+	"github.com/mitranim/gg"
+	"golang.org/x/term"
+)
+
+/*
+Puts our own stdin into raw mode for the duration of a `-P`/PTY run, so
+keystrokes (including control characters like Ctrl-C) pass through to the
+child's PTY unmodified instead of being line-buffered and echoed by our own
+terminal driver. A no-op when `Opt.Pty` is off or stdin isn't a terminal.
+*/
+type TermState struct {
+	main *Main
+	fd   int
+	prev *term.State
+}
+
+func (self *TermState) Init(main *Main) {
+	self.main = main
+	if !main.Opt.Pty {
+		return
+	}
+
+	self.fd = int(os.Stdin.Fd())
+	if !term.IsTerminal(self.fd) {
+		return
+	}
+
+	prev, err := term.MakeRaw(self.fd)
+	main.Opt.LogErr(err)
+	self.prev = prev
+}
+
+func (self *TermState) Deinit() {
+	if self.prev != nil {
+		gg.Nop1(term.Restore(self.fd, self.prev))
+		self.prev = nil
+	}
+}
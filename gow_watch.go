@@ -0,0 +1,75 @@
+package main
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/mitranim/gg"
+)
+
+/*
+Implemented by every filesystem-watching backend. `WatchInit` picks a
+concrete implementation based on `Opt.Watch`; everything downstream,
+starting with `Main.OnFsEvent`, only deals with `FsEvent` and doesn't care
+which backend produced it.
+*/
+type Watcher interface {
+	Init(*Main)
+	Deinit()
+	Run()
+}
+
+// Minimal shape of an FS event, regardless of which `Watcher` produced it.
+type FsEvent interface{ Path() string }
+
+// Default, fsnotify-backed `Watcher`. Relies on inotify/FSEvents/kqueue.
+type WatchNotify struct {
+	main    *Main
+	watcher *fsnotify.Watcher
+}
+
+func (self *WatchNotify) Init(main *Main) {
+	self.main = main
+
+	watcher, err := fsnotify.NewWatcher()
+	main.Opt.LogErr(err)
+	if err != nil {
+		return
+	}
+	self.watcher = watcher
+
+	for _, root := range main.Opt.WatchRoots() {
+		main.Opt.LogErr(watcher.Add(root))
+	}
+}
+
+func (self *WatchNotify) Deinit() {
+	if self.watcher != nil {
+		gg.Nop1(self.watcher.Close())
+		self.watcher = nil
+	}
+}
+
+func (self *WatchNotify) Run() {
+	if self.watcher == nil {
+		return
+	}
+
+	for {
+		select {
+		case event, ok := <-self.watcher.Events:
+			if !ok {
+				return
+			}
+			self.main.OnFsEvent(notifyEvent(event))
+
+		case err, ok := <-self.watcher.Errors:
+			if !ok {
+				return
+			}
+			self.main.Opt.LogErr(err)
+		}
+	}
+}
+
+type notifyEvent fsnotify.Event
+
+func (self notifyEvent) Path() string { return self.Name }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*
+Polling `Watcher`, selected via `-w poll`. Walks the watch roots on a timer
+and compares mtimes against the previous snapshot, rather than relying on
+inotify/FSEvents. Essential when gow runs inside a container with a bind
+mount from a macOS/Windows host, or against an NFS/SMB share, since native
+FS events are frequently dropped or never delivered in those setups.
+*/
+type WatchPoll struct {
+	main  *Main
+	done  chan struct{}
+	mtime map[string]time.Time
+}
+
+func (self *WatchPoll) Init(main *Main) {
+	self.main = main
+	self.done = make(chan struct{})
+	self.mtime = self.snapshot()
+}
+
+func (self *WatchPoll) Deinit() {
+	if self.done != nil {
+		close(self.done)
+		self.done = nil
+	}
+}
+
+func (self *WatchPoll) Run() {
+	interval := self.main.Opt.PollInterval()
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-self.done:
+			return
+		case <-tick.C:
+			self.poll()
+		}
+	}
+}
+
+func (self *WatchPoll) poll() {
+	next := self.snapshot()
+
+	for _, path := range diffSnapshots(self.mtime, next) {
+		self.main.OnFsEvent(pollEvent(path))
+	}
+
+	self.mtime = next
+}
+
+// Returns the paths that were added, modified (different mtime), or
+// removed between two snapshots. Pulled out of `poll` as a pure function
+// so the add/modify/remove logic can be tested without touching the
+// filesystem.
+func diffSnapshots(prev, next map[string]time.Time) []string {
+	var out []string
+
+	for path, mtime := range next {
+		if old, ok := prev[path]; !ok || !mtime.Equal(old) {
+			out = append(out, path)
+		}
+	}
+
+	for path := range prev {
+		if _, ok := next[path]; !ok {
+			out = append(out, path)
+		}
+	}
+
+	return out
+}
+
+func (self *WatchPoll) snapshot() map[string]time.Time {
+	out := map[string]time.Time{}
+	maxDepth := self.main.Opt.PollMaxDepth()
+
+	for _, root := range self.main.Opt.WatchRoots() {
+		rootDepth := strings.Count(filepath.Clean(root), string(filepath.Separator))
+
+		_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if self.main.Opt.IsIgnored(path) {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if entry.IsDir() {
+				depth := strings.Count(filepath.Clean(path), string(filepath.Separator)) - rootDepth
+				if maxDepth > 0 && depth >= maxDepth {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			out[path] = info.ModTime()
+			return nil
+		})
+	}
+	return out
+}
+
+// `FsEvent` emitted by `WatchPoll`. Carries only a path, like `notifyEvent`.
+type pollEvent string
+
+func (self pollEvent) Path() string { return string(self) }
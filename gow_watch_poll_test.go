@@ -0,0 +1,44 @@
+package main
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	t1 := time.Unix(2000, 0)
+
+	prev := map[string]time.Time{
+		`a.go`: t0,
+		`b.go`: t0,
+		`c.go`: t0,
+	}
+	next := map[string]time.Time{
+		`a.go`: t0, // unchanged
+		`b.go`: t1, // modified
+		`d.go`: t0, // added
+		// c.go removed
+	}
+
+	got := diffSnapshots(prev, next)
+	sort.Strings(got)
+
+	want := []string{`b.go`, `c.go`, `d.go`}
+	if len(got) != len(want) {
+		t.Fatalf(`diffSnapshots(%v, %v) = %v, want %v`, prev, next, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf(`diffSnapshots(%v, %v) = %v, want %v`, prev, next, got, want)
+		}
+	}
+}
+
+func TestDiffSnapshots_NoChanges(t *testing.T) {
+	snap := map[string]time.Time{`a.go`: time.Unix(1000, 0)}
+	if got := diffSnapshots(snap, snap); len(got) != 0 {
+		t.Fatalf(`expected no diff for identical snapshots, got %v`, got)
+	}
+}